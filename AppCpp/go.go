@@ -1,3 +1,9 @@
+//go:build ignore
+
+// This file is an early, standalone draft of the carpark model (superseded
+// by the sim package) and is only ever run directly via `go run AppCpp/go.go`.
+// It shares top-level declarations with AppCpp/main.go, so it is excluded
+// from `go build ./...`/`go vet ./...`.
 package main
 
 import (
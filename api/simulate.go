@@ -0,0 +1,107 @@
+// Package handler exposes the carpark model over HTTP as POST /simulate,
+// running sim.ModelRun in-process so it shares one code path with the CLI
+// in cmd/carpark.
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"traffic/metrics"
+	"traffic/sim"
+)
+
+// simulateRequest is the JSON body accepted by POST /simulate.
+type simulateRequest struct {
+	ArrivalRate int `json:"arrivalRate"`
+	ServiceTime int `json:"serviceTime"`
+	Spaces      int `json:"spaces"`
+	// Precision is only used when Mode is "tick".
+	Precision      int                `json:"precision"`
+	MaxHours       int                `json:"maxHours"`
+	Seed           int64              `json:"seed"`
+	Mode           sim.Mode           `json:"mode"`
+	PercentileMode sim.PercentileMode `json:"percentileMode"`
+	Quantiles      []float64          `json:"quantiles"`
+
+	// Replicates, if greater than 1, runs that many independent seeded
+	// replicates via sim.RunReplicated and reports confidence intervals
+	// instead of a single run.
+	Replicates int     `json:"replicates"`
+	Workers    int     `json:"workers"`
+	CILevel    float64 `json:"ciLevel"`
+}
+
+// validate rejects the field values sim.ModelRun assumes are positive:
+// arrivalRate and serviceTime size the cumulative-sum histograms, so a
+// negative value panics with makeslice: len out of range, and a zero
+// arrivalRate produces no arrivals, so CarsQueuedPercent (queued cars over
+// the arrival count) is a non-JSON-encodable NaN. Rejecting them here fails
+// the request cleanly instead.
+func (r simulateRequest) validate() error {
+	if r.ArrivalRate <= 0 {
+		return fmt.Errorf("arrivalRate must be positive, got %d", r.ArrivalRate)
+	}
+	if r.ServiceTime <= 0 {
+		return fmt.Errorf("serviceTime must be positive, got %d", r.ServiceTime)
+	}
+	if r.Spaces <= 0 {
+		return fmt.Errorf("spaces must be positive, got %d", r.Spaces)
+	}
+	return nil
+}
+
+// Handler returns the POST /simulate handler. If collector is non-nil, every
+// run updates it the same way CLI runs do under -metrics-addr, so callers
+// that run metrics.Serve on the collector's registry alongside this handler
+// can watch HTTP-driven simulations live too; pass nil to opt out.
+func Handler(collector *metrics.Collector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req simulateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := req.validate(); err != nil {
+			http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		inputs := sim.Inputs{
+			ArrivalRate:    req.ArrivalRate,
+			ServiceTime:    req.ServiceTime,
+			Spaces:         req.Spaces,
+			Precision:      req.Precision,
+			MaxHours:       req.MaxHours,
+			Seed:           req.Seed,
+			Mode:           req.Mode,
+			PercentileMode: req.PercentileMode,
+			Quantiles:      req.Quantiles,
+			Metrics:        collector,
+		}
+
+		var result any
+		if req.Replicates > 1 {
+			result = sim.RunReplicated(r.Context(), sim.ReplicatedInputs{
+				Inputs:     inputs,
+				Replicates: req.Replicates,
+				Workers:    req.Workers,
+				CILevel:    req.CILevel,
+			})
+		} else {
+			result = sim.ModelRun(inputs)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
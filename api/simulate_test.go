@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func doSimulate(t *testing.T, method, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, "/simulate", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	Handler(nil)(rec, req)
+	return rec
+}
+
+func TestHandlerSuccess(t *testing.T) {
+	rec := doSimulate(t, http.MethodPost, `{"arrivalRate":50,"serviceTime":90,"spaces":5,"maxHours":1,"seed":1}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("response body is not valid JSON: %v", err)
+	}
+	if _, ok := result["carsQueuedPercent"]; !ok {
+		t.Errorf("response missing carsQueuedPercent: %v", result)
+	}
+}
+
+func TestHandlerWrongMethod(t *testing.T) {
+	rec := doSimulate(t, http.MethodGet, "")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandlerInvalidJSON(t *testing.T) {
+	rec := doSimulate(t, http.MethodPost, `{not json`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+// TestHandlerRejectsNonPositiveFields guards against the
+// makeslice-len-out-of-range panic and the arrivalRate:0 division-by-zero
+// NaN that a 500 response used to leak to the caller: both arrivalRate and
+// serviceTime size the histograms sim.ModelRun preallocates, and a zero
+// arrivalRate leaves no arrivals for CarsQueuedPercent to divide by.
+func TestHandlerRejectsNonPositiveFields(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+	}{
+		{"negative arrivalRate", `{"arrivalRate":-5,"serviceTime":10,"spaces":5,"maxHours":1,"seed":1}`},
+		{"zero arrivalRate", `{"arrivalRate":0,"serviceTime":10,"spaces":5,"maxHours":1,"seed":1}`},
+		{"zero serviceTime", `{"arrivalRate":50,"serviceTime":0,"spaces":5,"maxHours":1,"seed":1}`},
+		{"zero spaces", `{"arrivalRate":50,"serviceTime":10,"spaces":0,"maxHours":1,"seed":1}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := doSimulate(t, http.MethodPost, c.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusBadRequest, rec.Body.String())
+			}
+		})
+	}
+}
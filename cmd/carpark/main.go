@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"traffic/metrics"
+	"traffic/sim"
+)
+
+func main() {
+	var inputs sim.Inputs
+	var percentileMode string
+	var quantiles string
+	var replicates int
+	var workers int
+	var ciLevel float64
+	var metricsAddr string
+	var mode string
+
+	// Parse command line arguments
+	// e.g., go run ./cmd/carpark -arrivalRate=50 -precision=3 -serviceTime=90 -spaces=5
+	flag.IntVar(&inputs.ArrivalRate, "arrivalRate", 100, "Number of cars arriving per hour")
+	flag.IntVar(&inputs.Precision, "precision", 1, "Precision required for arrival rate (ModeTick only)")
+	flag.IntVar(&inputs.ServiceTime, "serviceTime", 100, "How long a car stays in the carpark")
+	flag.IntVar(&inputs.Spaces, "spaces", 10, "Number of spaces in the carpark")
+	flag.IntVar(&inputs.MaxHours, "maxHours", sim.DefaultMaxHours, "Maximum simulated hours to search for a stable queue ratio")
+	flag.Int64Var(&inputs.Seed, "seed", 0, "Random seed (0 picks one from the current time)")
+	flag.StringVar(&percentileMode, "percentileMode", string(sim.PercentileModeHistogram), "Percentile estimator: histogram or tdigest")
+	flag.StringVar(&quantiles, "quantiles", "", "Comma-separated extra quantiles to report (0-1), e.g. 0.25,0.75")
+	flag.IntVar(&replicates, "replicates", 1, "Number of independent seeded runs to average over")
+	flag.IntVar(&workers, "workers", 0, "Worker pool size for replicates (0 picks runtime.NumCPU())")
+	flag.Float64Var(&ciLevel, "ci-level", 0.95, "Confidence level for the reported interval: 0.90, 0.95 or 0.99")
+	flag.StringVar(&metricsAddr, "metrics-addr", "", "If set, serve /metrics and /debug/pprof/* on this address while the model runs")
+	flag.StringVar(&mode, "mode", string(sim.ModeEvent), "Simulation engine: event (default, continuous-time) or tick (legacy, for regression testing)")
+	flag.Parse()
+
+	inputs.Mode = sim.Mode(mode)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	if metricsAddr != "" {
+		registry := prometheus.NewRegistry()
+		inputs.Metrics = metrics.NewCollector(registry)
+		go func() {
+			if err := metrics.Serve(metricsAddr, registry); err != nil {
+				logger.Error("metrics server stopped", "error", err)
+			}
+		}()
+		logger.Info("serving metrics", "addr", metricsAddr)
+	}
+
+	inputs.PercentileMode = sim.PercentileMode(percentileMode)
+	for _, q := range strings.Split(quantiles, ",") {
+		q = strings.TrimSpace(q)
+		if q == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(q, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid quantile %q: %v\n", q, err)
+			os.Exit(1)
+		}
+		inputs.Quantiles = append(inputs.Quantiles, value)
+	}
+
+	logger.Info("running model", "arrivalRate", inputs.ArrivalRate, "serviceTime", inputs.ServiceTime, "spaces", inputs.Spaces, "mode", inputs.Mode, "percentileMode", inputs.PercentileMode, "replicates", replicates)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	var encodeErr error
+	if replicates > 1 {
+		result := sim.RunReplicated(context.Background(), sim.ReplicatedInputs{
+			Inputs:     inputs,
+			Replicates: replicates,
+			Workers:    workers,
+			CILevel:    ciLevel,
+		})
+		encodeErr = encoder.Encode(result)
+	} else {
+		result := sim.ModelRun(inputs)
+		encodeErr = encoder.Encode(result)
+	}
+	if encodeErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode result: %v\n", encodeErr)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,69 @@
+// Package metrics exposes the carpark_sim_* Prometheus metrics and pprof
+// endpoints for long-running simulations, so both CLI runs (behind
+// -metrics-addr) and the HTTP handler path can be watched live instead of
+// waiting for completion.
+package metrics
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Collector holds the metrics updated from inside sim.ModelRun's loop.
+type Collector struct {
+	ArrivalsTotal prometheus.Counter
+	QueuedTotal   prometheus.Counter
+	CurrentQueue  prometheus.Gauge
+	CurrentParked prometheus.Gauge
+	HoursElapsed  prometheus.Gauge
+	QueueSeconds  prometheus.Histogram
+}
+
+// NewCollector registers a fresh set of carpark_sim_* metrics with reg.
+func NewCollector(reg prometheus.Registerer) *Collector {
+	factory := promauto.With(reg)
+	return &Collector{
+		ArrivalsTotal: factory.NewCounter(prometheus.CounterOpts{
+			Name: "carpark_sim_arrivals_total",
+			Help: "Total number of car arrivals processed.",
+		}),
+		QueuedTotal: factory.NewCounter(prometheus.CounterOpts{
+			Name: "carpark_sim_queued_total",
+			Help: "Total number of arrivals that had to queue instead of parking immediately.",
+		}),
+		CurrentQueue: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "carpark_sim_current_queue",
+			Help: "Current number of cars queued.",
+		}),
+		CurrentParked: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "carpark_sim_current_parked",
+			Help: "Current number of cars parked.",
+		}),
+		HoursElapsed: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "carpark_sim_hours_elapsed",
+			Help: "Simulated hours elapsed so far in the current run.",
+		}),
+		QueueSeconds: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:    "carpark_sim_queue_seconds",
+			Help:    "Distribution of the queue length (cars waiting), observed every simulated second in tick mode and once per event interval in event mode.",
+			Buckets: prometheus.DefBuckets,
+		}),
+	}
+}
+
+// Serve starts a blocking HTTP server on addr exposing /metrics for reg and
+// /debug/pprof/*. Callers typically run it in a goroutine.
+func Serve(addr string, reg *prometheus.Registry) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return http.ListenAndServe(addr, mux)
+}
@@ -0,0 +1,179 @@
+package sim
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ReplicatedInputs configures a batch of independent, seeded ModelRun
+// replicates.
+type ReplicatedInputs struct {
+	Inputs
+
+	// Replicates is the number of independent runs to average over.
+	Replicates int
+	// Workers bounds how many replicates run concurrently. Defaults to
+	// runtime.NumCPU() when zero.
+	Workers int
+	// CILevel is the confidence level for the reported interval: 0.90,
+	// 0.95 (the default) or 0.99.
+	CILevel float64
+}
+
+// Stat summarizes a metric across replicates.
+type Stat struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
+	CILow  float64 `json:"ciLow"`
+	CIHigh float64 `json:"ciHigh"`
+}
+
+// PercentileStat is a Stat for one reported percentile.
+type PercentileStat struct {
+	Percent float64 `json:"percent"`
+	Stat
+}
+
+// ReplicatedResult aggregates Replicates independent ModelRun outcomes.
+type ReplicatedResult struct {
+	Replicates        int              `json:"replicates"`
+	CarsQueuedPercent Stat             `json:"carsQueuedPercent"`
+	AvgQueueTime      Stat             `json:"avgQueueTimePerArrival"`
+	ParkedPercentiles []PercentileStat `json:"parkedPercentiles"`
+	QueuedPercentiles []PercentileStat `json:"queuedPercentiles"`
+}
+
+// RunReplicated launches Replicates seeded ModelRun calls across a pool of
+// Workers goroutines, aggregates them as they complete on a results channel,
+// and reports the mean, standard deviation and confidence interval for each
+// metric. It stops launching new replicates as soon as ctx is done.
+func RunReplicated(ctx context.Context, inp ReplicatedInputs) ReplicatedResult {
+	replicates := inp.Replicates
+	if replicates <= 0 {
+		replicates = 1
+	}
+	workers := inp.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	baseSeed := inp.Seed
+	if baseSeed == 0 {
+		baseSeed = time.Now().UnixNano()
+	}
+
+	seeds := make(chan int64)
+	results := make(chan Result, replicates)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seed := range seeds {
+				runInputs := inp.Inputs
+				runInputs.Seed = seed
+				results <- ModelRun(runInputs)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(seeds)
+		for i := 0; i < replicates; i++ {
+			select {
+			case seeds <- baseSeed + int64(i):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	runs := make([]Result, 0, replicates)
+	for r := range results {
+		runs = append(runs, r)
+	}
+
+	return aggregate(runs, zScore(inp.CILevel))
+}
+
+// zScore maps a confidence level to its two-tailed normal z-score. A level is
+// rounded down to the nearest of 0.90, 0.95 and 0.99; 0 (unset) and anything
+// below 0.90 get the 95% z-score.
+func zScore(ciLevel float64) float64 {
+	switch {
+	case ciLevel >= 0.99:
+		return 2.58
+	case ciLevel >= 0.95, ciLevel == 0:
+		return 1.96
+	case ciLevel >= 0.90:
+		return 1.645
+	default:
+		return 1.96
+	}
+}
+
+func aggregate(runs []Result, z float64) ReplicatedResult {
+	result := ReplicatedResult{Replicates: len(runs)}
+	if len(runs) == 0 {
+		return result
+	}
+
+	queuedPercent := make([]float64, len(runs))
+	avgQueueTime := make([]float64, len(runs))
+	for i, r := range runs {
+		queuedPercent[i] = r.CarsQueuedPercent
+		avgQueueTime[i] = r.AvgQueueTimePerArrival
+	}
+	result.CarsQueuedPercent = stat(queuedPercent, z)
+	result.AvgQueueTime = stat(avgQueueTime, z)
+
+	for pi := range runs[0].ParkedPercentiles {
+		parked := make([]float64, len(runs))
+		queued := make([]float64, len(runs))
+		for ri, r := range runs {
+			parked[ri] = r.ParkedPercentiles[pi].Value
+			queued[ri] = r.QueuedPercentiles[pi].Value
+		}
+		percent := runs[0].ParkedPercentiles[pi].Percent
+		result.ParkedPercentiles = append(result.ParkedPercentiles, PercentileStat{Percent: percent, Stat: stat(parked, z)})
+		result.QueuedPercentiles = append(result.QueuedPercentiles, PercentileStat{Percent: percent, Stat: stat(queued, z)})
+	}
+
+	return result
+}
+
+// stat computes the mean, standard deviation and a z*sigma/sqrt(n)
+// confidence interval for a set of replicate observations.
+func stat(values []float64, z float64) Stat {
+	n := float64(len(values))
+	if n == 0 {
+		return Stat{}
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / n
+
+	var stdDev float64
+	if n >= 2 {
+		variance := 0.0
+		for _, v := range values {
+			diff := v - mean
+			variance += diff * diff
+		}
+		stdDev = math.Sqrt(variance / (n - 1))
+	}
+
+	margin := z * stdDev / math.Sqrt(n)
+	return Stat{Mean: mean, StdDev: stdDev, CILow: mean - margin, CIHigh: mean + margin}
+}
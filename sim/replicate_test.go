@@ -0,0 +1,66 @@
+package sim
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestZScore(t *testing.T) {
+	cases := map[float64]float64{
+		0:    1.96,
+		0.90: 1.645,
+		0.95: 1.96,
+		0.99: 2.58,
+	}
+	for level, want := range cases {
+		if got := zScore(level); got != want {
+			t.Errorf("zScore(%v) = %v, want %v", level, got, want)
+		}
+	}
+}
+
+func TestStat(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5}
+	s := stat(values, 1.96)
+
+	if s.Mean != 3 {
+		t.Errorf("Mean = %v, want 3", s.Mean)
+	}
+	wantStdDev := math.Sqrt(2.5)
+	if math.Abs(s.StdDev-wantStdDev) > 1e-9 {
+		t.Errorf("StdDev = %v, want %v", s.StdDev, wantStdDev)
+	}
+	margin := 1.96 * wantStdDev / math.Sqrt(5)
+	if math.Abs(s.CILow-(3-margin)) > 1e-9 || math.Abs(s.CIHigh-(3+margin)) > 1e-9 {
+		t.Errorf("CI = [%v, %v], want [%v, %v]", s.CILow, s.CIHigh, 3-margin, 3+margin)
+	}
+}
+
+// TestRunReplicatedAggregates checks that RunReplicated launches the
+// requested number of runs and that the aggregated stats bracket the mean
+// with a non-negative confidence interval.
+func TestRunReplicatedAggregates(t *testing.T) {
+	result := RunReplicated(context.Background(), ReplicatedInputs{
+		Inputs: Inputs{
+			ArrivalRate: 50,
+			ServiceTime: 90,
+			Spaces:      5,
+			Precision:   1,
+			MaxHours:    5,
+		},
+		Replicates: 5,
+		Workers:    2,
+		CILevel:    0.95,
+	})
+
+	if result.Replicates != 5 {
+		t.Fatalf("Replicates = %v, want 5", result.Replicates)
+	}
+	if result.CarsQueuedPercent.CILow > result.CarsQueuedPercent.Mean || result.CarsQueuedPercent.CIHigh < result.CarsQueuedPercent.Mean {
+		t.Errorf("CarsQueuedPercent CI [%v, %v] does not bracket mean %v", result.CarsQueuedPercent.CILow, result.CarsQueuedPercent.CIHigh, result.CarsQueuedPercent.Mean)
+	}
+	if len(result.ParkedPercentiles) == 0 {
+		t.Error("expected ParkedPercentiles to be populated")
+	}
+}
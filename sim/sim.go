@@ -0,0 +1,463 @@
+// Package sim implements the carpark occupancy/queueing model shared by
+// the CLI (cmd/carpark) and the HTTP handler (api/simulate.go), so both
+// entry points run exactly the same code path.
+package sim
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"time"
+
+	"traffic/metrics"
+	"traffic/tdigest"
+)
+
+// DefaultMaxHours bounds how long a run searches for a stable queue ratio
+// before giving up and reporting whatever it has.
+const DefaultMaxHours = 3000
+
+// PercentileMode selects how ModelRun estimates occupancy/queue percentiles.
+type PercentileMode string
+
+const (
+	// PercentileModeHistogram (the default) walks an exact cumulative-sum
+	// histogram per percentile. Its size is proportional to
+	// arrivalRate*serviceTime, which gets expensive for large parks.
+	PercentileModeHistogram PercentileMode = "histogram"
+	// PercentileModeTDigest feeds every sampled occupancy/queue length into
+	// a t-digest and answers percentile queries from it in near-constant
+	// time, trading exactness for bounded memory and speed.
+	PercentileModeTDigest PercentileMode = "tdigest"
+)
+
+// Mode selects the simulation engine.
+type Mode string
+
+const (
+	// ModeEvent (the default) advances simulated time event by event: the
+	// next arrival is drawn from an exponential distribution and departures
+	// come off a min-heap keyed by departure time, so cost no longer
+	// depends on the number of spaces.
+	ModeEvent Mode = "event"
+	// ModeTick is the legacy per-second scheduler, kept only for regression
+	// testing against ModeEvent. It decrements every parked car's
+	// remaining time on every simulated second and honours Precision.
+	ModeTick Mode = "tick"
+)
+
+// Inputs configures a single model run.
+type Inputs struct {
+	ArrivalRate int
+	ServiceTime int
+	Spaces      int
+	// Precision is only used by ModeTick; ModeEvent draws arrivals from a
+	// continuous exponential distribution and has no notion of precision.
+	Precision int
+	MaxHours  int
+	Seed      int64
+
+	// Mode selects the simulation engine. Defaults to ModeEvent when empty.
+	Mode Mode
+
+	// PercentileMode selects the percentile estimator. Defaults to
+	// PercentileModeHistogram when empty.
+	PercentileMode PercentileMode
+	// Quantiles are additional arbitrary quantiles (0-1) to report
+	// alongside the standard 10/20/.../99 set.
+	Quantiles []float64
+
+	// Metrics, when set, is updated from inside the run loop with live
+	// arrival/queue/occupancy counters so long runs can be watched via
+	// Prometheus instead of waiting for completion.
+	Metrics *metrics.Collector
+}
+
+// Percentile is a single percentile sample: the occupancy/queue length at
+// which the requested percentage of surveyed time was reached (exact under
+// PercentileModeHistogram, estimated under PercentileModeTDigest).
+type Percentile struct {
+	Percent float64 `json:"percent"`
+	Value   float64 `json:"value"`
+}
+
+// Result is the outcome of a single ModelRun.
+type Result struct {
+	ElapsedMs              int64        `json:"elapsedMs"`
+	StableHours            float64      `json:"stableHours"`
+	StabilityFound         bool         `json:"stabilityFound"`
+	CarsQueuedPercent      float64      `json:"carsQueuedPercent"`
+	AvgQueueTimePerArrival float64      `json:"avgQueueTimePerArrival"`
+	AvgQueueTimePerQueued  float64      `json:"avgQueueTimePerQueuedVehicle"`
+	MinSpacesRequested     float64      `json:"minSpacesRequested"`
+	MinSpacesModel         float64      `json:"minSpacesModel"`
+	ParkedPercentiles      []Percentile `json:"parkedPercentiles"`
+	QueuedPercentiles      []Percentile `json:"queuedPercentiles"`
+}
+
+var reportedPercentiles = []float64{10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 98, 99}
+
+// percentageOfTime calculates the index at which a cumulative sum of weights
+// in the list reaches or exceeds the specified percentage of the total.
+func percentageOfTime(percent float64, list []float64) int {
+	cumulativeSum := 0.0
+	totalTime := 0.0
+	for _, value := range list {
+		totalTime += value
+	}
+	targetSum := totalTime * percent / 100
+	for index, value := range list {
+		cumulativeSum += value
+		if cumulativeSum >= targetSum {
+			return index
+		}
+	}
+	return 0
+}
+
+// buildPercentiles reports the standard percentile set plus any caller
+// requested Quantiles, reading from the histogram or the t-digests
+// depending on which one the run populated.
+func buildPercentiles(inp Inputs, useTDigest bool, parkedDigest, queuedDigest *tdigest.TDigest, countCarsParked, countCarsQueued []float64) (parked, queued []Percentile) {
+	quantiles := make([]float64, 0, len(reportedPercentiles)+len(inp.Quantiles))
+	for _, p := range reportedPercentiles {
+		quantiles = append(quantiles, p/100)
+	}
+	quantiles = append(quantiles, inp.Quantiles...)
+
+	seen := make(map[float64]bool, len(quantiles))
+	for _, q := range quantiles {
+		if seen[q] {
+			continue
+		}
+		seen[q] = true
+
+		percent := q * 100
+		var parkedValue, queuedValue float64
+		if useTDigest {
+			parkedValue = parkedDigest.Quantile(q)
+			queuedValue = queuedDigest.Quantile(q)
+		} else {
+			parkedValue = float64(percentageOfTime(percent, countCarsParked))
+			queuedValue = float64(percentageOfTime(percent, countCarsQueued))
+		}
+		parked = append(parked, Percentile{Percent: percent, Value: parkedValue})
+		queued = append(queued, Percentile{Percent: percent, Value: queuedValue})
+	}
+	return parked, queued
+}
+
+// ModelRun simulates cars arriving at a park with a fixed number of spaces,
+// queueing when it is full, and returns the demand percentiles and queueing
+// statistics collected once the queued-car ratio stabilizes (or MaxHours of
+// simulated time elapses, whichever comes first).
+func ModelRun(inp Inputs) Result {
+	if inp.Mode == ModeTick {
+		return modelRunTick(inp)
+	}
+	return modelRunEvent(inp)
+}
+
+// modelRunEvent is the default engine: it advances simulated time from event
+// to event instead of ticking every second, keeping parked cars in a
+// min-heap keyed by departure time and drawing arrivals from an exponential
+// distribution. This avoids the O(spaces) per-tick decrement loop that
+// modelRunTick needs.
+func modelRunEvent(inp Inputs) Result {
+	maxHours := inp.MaxHours
+	if maxHours <= 0 {
+		maxHours = DefaultMaxHours
+	}
+	seed := inp.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	useTDigest := inp.PercentileMode == PercentileModeTDigest
+
+	startTime := time.Now()
+	maxSeconds := float64(maxHours * 3600)
+	arrivalRatePerSecond := float64(inp.ArrivalRate) / 3600
+
+	var countCarsParked, countCarsQueued []float64
+	var parkedDigest, queuedDigest *tdigest.TDigest
+	if useTDigest {
+		parkedDigest = tdigest.New(tdigest.DefaultCompression)
+		queuedDigest = tdigest.New(tdigest.DefaultCompression)
+	} else {
+		countCarsParked = make([]float64, inp.spacesOrOne()+1)
+		countCarsQueued = make([]float64, inp.ArrivalRate*inp.ServiceTime+1)
+	}
+
+	departures := &departureHeap{}
+	heap.Init(departures)
+
+	currentTime := 0.0
+	nextArrival := rng.ExpFloat64() / arrivalRatePerSecond
+	nextCheckpoint := 3600.0 * 1000
+	queueLen := 0
+	countArrivals := 0
+	carsQueued := 0
+	queueTimeWeighted := 0.0
+	queueTest := 0.0
+	hours := 0.0
+	stabilityFound := false
+
+	for currentTime < maxSeconds {
+		nextDeparture := math.Inf(1)
+		if departures.Len() > 0 {
+			nextDeparture = (*departures)[0]
+		}
+		nextEvent := math.Min(nextArrival, nextDeparture)
+		if nextEvent > maxSeconds {
+			nextEvent = maxSeconds
+		}
+
+		if elapsed := nextEvent - currentTime; elapsed > 0 {
+			if useTDigest {
+				parkedDigest.Add(float64(departures.Len()), elapsed)
+				queuedDigest.Add(float64(queueLen), elapsed)
+			} else {
+				countCarsParked[departures.Len()] += elapsed
+				countCarsQueued[clampIndex(queueLen, len(countCarsQueued)-1)] += elapsed
+			}
+			queueTimeWeighted += float64(queueLen) * elapsed
+			if inp.Metrics != nil {
+				inp.Metrics.CurrentQueue.Set(float64(queueLen))
+				inp.Metrics.CurrentParked.Set(float64(departures.Len()))
+				inp.Metrics.QueueSeconds.Observe(float64(queueLen))
+				inp.Metrics.HoursElapsed.Set(nextEvent / 3600)
+			}
+		}
+		currentTime = nextEvent
+
+		for countArrivals > 0 && currentTime >= nextCheckpoint && !stabilityFound {
+			hours = nextCheckpoint / 3600
+			if inp.Metrics != nil {
+				inp.Metrics.HoursElapsed.Set(hours)
+			}
+			currentQueueRatio := float64(carsQueued) / float64(countArrivals)
+			if math.Abs(queueTest-currentQueueRatio) <= 1e-6 {
+				stabilityFound = true
+				break
+			}
+			queueTest = currentQueueRatio
+			nextCheckpoint += 36000
+		}
+		if stabilityFound || currentTime >= maxSeconds {
+			if !stabilityFound {
+				hours = currentTime / 3600
+			}
+			break
+		}
+
+		if nextEvent == nextArrival {
+			countArrivals++
+			if inp.Metrics != nil {
+				inp.Metrics.ArrivalsTotal.Inc()
+			}
+			if departures.Len() < inp.Spaces {
+				heap.Push(departures, currentTime+float64(inp.ServiceTime))
+			} else {
+				queueLen++
+				carsQueued++
+				if inp.Metrics != nil {
+					inp.Metrics.QueuedTotal.Inc()
+				}
+			}
+			nextArrival = currentTime + rng.ExpFloat64()/arrivalRatePerSecond
+		} else {
+			heap.Pop(departures)
+			if queueLen > 0 {
+				queueLen--
+				heap.Push(departures, currentTime+float64(inp.ServiceTime))
+			}
+		}
+	}
+
+	result := Result{
+		ElapsedMs:          time.Since(startTime).Milliseconds(),
+		StableHours:        hours,
+		StabilityFound:     stabilityFound,
+		CarsQueuedPercent:  float64(carsQueued*100) / float64(countArrivals),
+		MinSpacesRequested: float64(countArrivals) / hours * float64(inp.ServiceTime) / 3600,
+		MinSpacesModel:     float64(inp.ArrivalRate*inp.ServiceTime) / 3600,
+	}
+	if carsQueued > 0 {
+		result.AvgQueueTimePerArrival = queueTimeWeighted / float64(countArrivals)
+		result.AvgQueueTimePerQueued = queueTimeWeighted / float64(carsQueued)
+	}
+	result.ParkedPercentiles, result.QueuedPercentiles = buildPercentiles(inp, useTDigest, parkedDigest, queuedDigest, countCarsParked, countCarsQueued)
+
+	return result
+}
+
+// modelRunTick is the legacy scheduler, preserved behind Mode: ModeTick for
+// regression testing against modelRunEvent. It decrements every parked car's
+// remaining time on every simulated second, which is O(spaces) per tick.
+func modelRunTick(inp Inputs) Result {
+	maxHours := inp.MaxHours
+	if maxHours <= 0 {
+		maxHours = DefaultMaxHours
+	}
+	precision := inp.Precision
+	if precision <= 0 {
+		precision = 1
+	}
+	seed := inp.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	useTDigest := inp.PercentileMode == PercentileModeTDigest
+
+	startTime := time.Now()
+	countArrivals := 0
+	var countCarsParked, countCarsQueued []float64
+	var parkedDigest, queuedDigest *tdigest.TDigest
+	if useTDigest {
+		parkedDigest = tdigest.New(tdigest.DefaultCompression)
+		queuedDigest = tdigest.New(tdigest.DefaultCompression)
+	} else {
+		countCarsParked = make([]float64, inp.spacesOrOne()+1)
+		countCarsQueued = make([]float64, inp.ArrivalRate*inp.ServiceTime+1)
+	}
+	carsParked := []int{}
+	carsQueued := 0
+	queue := 0
+	queueTime := 0
+	queueTest := 0.0
+	hours := 0.0
+	stabilityFound := false
+
+	lastSecond := 0
+	for i := 1; i <= maxHours*3600; i++ {
+		lastSecond = i
+		if i > 3600*1000 && i%36000 == 0 {
+			currentQueueRatio := float64(carsQueued) / float64(countArrivals)
+			hours = float64(i) / 3600
+			if inp.Metrics != nil {
+				inp.Metrics.HoursElapsed.Set(hours)
+			}
+			if math.Abs(queueTest-currentQueueRatio) <= 1e-6 {
+				stabilityFound = true
+				break
+			}
+			queueTest = currentQueueRatio
+		}
+
+		// Check if a new car arrived and add to the car park
+		arrival := rng.Intn(3600*precision) + 1
+		if arrival <= inp.ArrivalRate*precision {
+			countArrivals++
+			if inp.Metrics != nil {
+				inp.Metrics.ArrivalsTotal.Inc()
+			}
+			if len(carsParked) < inp.Spaces {
+				carsParked = append(carsParked, inp.ServiceTime)
+			} else {
+				queue++
+				carsQueued++
+				if inp.Metrics != nil {
+					inp.Metrics.QueuedTotal.Inc()
+				}
+			}
+		}
+
+		// Count current car park utilization
+		index := max(len(carsParked), 0)
+		if useTDigest {
+			parkedDigest.Add(float64(index), 1)
+			queuedDigest.Add(float64(queue), 1)
+		} else {
+			countCarsParked[index]++
+			countCarsQueued[clampIndex(queue, len(countCarsQueued)-1)]++
+		}
+		queueTime += queue
+		if inp.Metrics != nil {
+			inp.Metrics.CurrentQueue.Set(float64(queue))
+			inp.Metrics.CurrentParked.Set(float64(len(carsParked)))
+			inp.Metrics.QueueSeconds.Observe(float64(queue))
+			inp.Metrics.HoursElapsed.Set(float64(i) / 3600)
+		}
+
+		// Reduce parked cars' time remaining
+		if len(carsParked) >= 1 {
+			for j := range carsParked {
+				carsParked[j]--
+			}
+
+			// Move finished cars out and queued cars in
+			if carsParked[0] == 0 {
+				carsParked = carsParked[1:]
+				if queue > 0 {
+					carsParked = append(carsParked, inp.ServiceTime)
+					queue--
+				}
+			}
+		}
+	}
+	if !stabilityFound {
+		hours = float64(lastSecond) / 3600
+	}
+
+	result := Result{
+		ElapsedMs:          time.Since(startTime).Milliseconds(),
+		StableHours:        hours,
+		StabilityFound:     stabilityFound,
+		CarsQueuedPercent:  float64(carsQueued*100) / float64(countArrivals),
+		MinSpacesRequested: float64(countArrivals) / hours * float64(inp.ServiceTime) / 3600,
+		MinSpacesModel:     float64(inp.ArrivalRate*inp.ServiceTime) / 3600,
+	}
+	if carsQueued > 0 {
+		result.AvgQueueTimePerArrival = float64(queueTime) / float64(countArrivals)
+		result.AvgQueueTimePerQueued = float64(queueTime) / float64(carsQueued)
+	}
+	result.ParkedPercentiles, result.QueuedPercentiles = buildPercentiles(inp, useTDigest, parkedDigest, queuedDigest, countCarsParked, countCarsQueued)
+
+	return result
+}
+
+func (inp Inputs) spacesOrOne() int {
+	if inp.Spaces <= 0 {
+		return 1
+	}
+	return inp.Spaces
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// clampIndex folds index into [0, maxIndex] so an oversaturated park (whose
+// queue length can exceed the histogram's preallocated size) accumulates
+// into the last bucket instead of panicking with an out-of-range index.
+func clampIndex(index, maxIndex int) int {
+	if index > maxIndex {
+		return maxIndex
+	}
+	if index < 0 {
+		return 0
+	}
+	return index
+}
+
+// departureHeap is a min-heap of departure timestamps (simulated seconds
+// since the run started), used by modelRunEvent to find the next car to
+// leave without scanning every parked car.
+type departureHeap []float64
+
+func (h departureHeap) Len() int            { return len(h) }
+func (h departureHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h departureHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *departureHeap) Push(x interface{}) { *h = append(*h, x.(float64)) }
+func (h *departureHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	v := old[n-1]
+	*h = old[:n-1]
+	return v
+}
@@ -0,0 +1,79 @@
+package sim
+
+import "testing"
+
+// TestEventTickAgree checks that modelRunEvent and modelRunTick, run with the
+// same inputs, agree closely enough to trust ModeEvent as a faster
+// replacement for the legacy ModeTick scheduler.
+func TestEventTickAgree(t *testing.T) {
+	inp := Inputs{
+		ArrivalRate: 50,
+		ServiceTime: 90,
+		Spaces:      5,
+		Precision:   1,
+		MaxHours:    1500,
+		Seed:        42,
+	}
+
+	eventInp := inp
+	eventInp.Mode = ModeEvent
+	eventResult := ModelRun(eventInp)
+
+	tickInp := inp
+	tickInp.Mode = ModeTick
+	tickResult := ModelRun(tickInp)
+
+	if diff := eventResult.CarsQueuedPercent - tickResult.CarsQueuedPercent; diff < -2 || diff > 2 {
+		t.Errorf("CarsQueuedPercent differs too much: event=%v tick=%v", eventResult.CarsQueuedPercent, tickResult.CarsQueuedPercent)
+	}
+
+	for i := range eventResult.ParkedPercentiles {
+		ep := eventResult.ParkedPercentiles[i]
+		tp := tickResult.ParkedPercentiles[i]
+		if ep.Percent != tp.Percent {
+			t.Fatalf("percentile sets diverged at index %d: %v vs %v", i, ep.Percent, tp.Percent)
+		}
+		if diff := ep.Value - tp.Value; diff < -2 || diff > 2 {
+			t.Errorf("parked percentile %v differs too much: event=%v tick=%v", ep.Percent, ep.Value, tp.Value)
+		}
+	}
+}
+
+// TestModelRunEventQueueOverflow exercises an oversaturated park, where the
+// queue grows far beyond the histogram's preallocated size, to guard against
+// the out-of-range panic this used to trigger.
+func TestModelRunEventQueueOverflow(t *testing.T) {
+	result := ModelRun(Inputs{
+		ArrivalRate: 7200,
+		ServiceTime: 1,
+		Spaces:      1,
+		MaxHours:    3,
+		Seed:        1,
+	})
+	if result.CarsQueuedPercent <= 0 {
+		t.Errorf("expected an oversaturated park to report queued cars, got %v", result.CarsQueuedPercent)
+	}
+}
+
+// TestStableHoursShortRun checks that a run shorter than the first
+// stabilization checkpoint (1000 hours) still reports a usable StableHours
+// and MinSpacesRequested instead of 0 / +Inf.
+func TestStableHoursShortRun(t *testing.T) {
+	for _, mode := range []Mode{ModeEvent, ModeTick} {
+		result := ModelRun(Inputs{
+			ArrivalRate: 50,
+			ServiceTime: 90,
+			Spaces:      5,
+			Precision:   1,
+			MaxHours:    5,
+			Seed:        7,
+			Mode:        mode,
+		})
+		if result.StableHours <= 0 {
+			t.Errorf("mode %v: StableHours = %v, want > 0", mode, result.StableHours)
+		}
+		if result.MinSpacesRequested > 1e6 {
+			t.Errorf("mode %v: MinSpacesRequested = %v, want a finite, reasonable value", mode, result.MinSpacesRequested)
+		}
+	}
+}
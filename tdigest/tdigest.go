@@ -0,0 +1,198 @@
+// Package tdigest implements a streaming t-digest: an approximate
+// percentile estimator that can be fed samples online and queried for any
+// quantile in near-constant time with bounded relative error, in place of
+// walking a full histogram for every percentile.
+package tdigest
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// DefaultCompression is a reasonable default for delta: higher values give
+// tighter accuracy at the cost of more centroids.
+const DefaultCompression = 100.0
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigest is a compressed summary of a distribution built from weighted
+// samples added one at a time.
+type TDigest struct {
+	delta       float64
+	centroids   []centroid
+	totalWeight float64
+	compressing bool
+}
+
+// New creates a TDigest with the given compression parameter delta. Larger
+// delta means more centroids and better accuracy.
+func New(delta float64) *TDigest {
+	if delta <= 0 {
+		delta = DefaultCompression
+	}
+	return &TDigest{delta: delta}
+}
+
+// Add records a sample of the given value with the given weight (use 1 for
+// a single observation).
+func (t *TDigest) Add(value float64, weight float64) {
+	if weight <= 0 {
+		return
+	}
+
+	if idx, ok := t.closest(value); ok {
+		c := &t.centroids[idx]
+		// An exact-value merge changes no one's mean, so it can never add
+		// interpolation error: let it through regardless of sizeBound. Without
+		// this, a value that recurs far more often than sizeBound allows at
+		// its quantile (e.g. a mostly-zero queue-length histogram) would spin
+		// off a fresh low-weight centroid on every single Add.
+		if c.mean == value {
+			c.weight += weight
+			t.totalWeight += weight
+			t.maybeCompress()
+			return
+		}
+		q := t.cumulativeQuantile(idx)
+		if c.weight+weight <= t.sizeBound(q) {
+			c.mean += (value - c.mean) * weight / (c.weight + weight)
+			c.weight += weight
+			t.totalWeight += weight
+			t.maybeCompress()
+			return
+		}
+	}
+
+	t.insert(centroid{mean: value, weight: weight})
+	t.totalWeight += weight
+	t.maybeCompress()
+}
+
+// Quantile returns an estimate of the value at quantile q (0 <= q <= 1).
+func (t *TDigest) Quantile(q float64) float64 {
+	if len(t.centroids) == 0 {
+		return 0
+	}
+	if len(t.centroids) == 1 {
+		return t.centroids[0].mean
+	}
+
+	target := q * t.totalWeight
+	cumulative := 0.0
+	for i, c := range t.centroids {
+		next := cumulative + c.weight
+		if next >= target || i == len(t.centroids)-1 {
+			if i == 0 {
+				return c.mean
+			}
+			prev := t.centroids[i-1]
+			prevCumulative := cumulative - prev.weight/2
+			thisCumulative := cumulative + c.weight/2
+			if thisCumulative == prevCumulative {
+				return c.mean
+			}
+			frac := (target - prevCumulative) / (thisCumulative - prevCumulative)
+			return prev.mean + frac*(c.mean-prev.mean)
+		}
+		cumulative = next
+	}
+	return t.centroids[len(t.centroids)-1].mean
+}
+
+// Merge folds other's centroids into t as additional weighted samples. Each
+// one goes through Add, so it merges under the same size bound, and a full
+// recompress runs only once the centroid count passes 10*delta.
+func (t *TDigest) Merge(other *TDigest) {
+	if other == nil {
+		return
+	}
+	for _, c := range other.centroids {
+		t.Add(c.mean, c.weight)
+	}
+}
+
+// closest returns the index of the centroid whose mean is nearest value. When
+// several centroids share exactly value as their mean, it returns the
+// heaviest of them rather than the leftmost: always picking the same
+// positional slot would merge every new sample into whichever centroid for
+// that value happened to be inserted first, leaving the established,
+// higher-weight centroid next to it untouched. For data with many repeated
+// values (e.g. a queue-length histogram that's mostly 0) that made every Add
+// fail its sizeBound check and split off a fresh low-weight centroid instead
+// of merging, so the digest never compressed.
+func (t *TDigest) closest(value float64) (int, bool) {
+	if len(t.centroids) == 0 {
+		return 0, false
+	}
+	i := sort.Search(len(t.centroids), func(i int) bool { return t.centroids[i].mean >= value })
+	if i < len(t.centroids) && t.centroids[i].mean == value {
+		best := i
+		for j := i + 1; j < len(t.centroids) && t.centroids[j].mean == value; j++ {
+			if t.centroids[j].weight > t.centroids[best].weight {
+				best = j
+			}
+		}
+		return best, true
+	}
+	if i == 0 {
+		return 0, true
+	}
+	if i == len(t.centroids) {
+		return i - 1, true
+	}
+	if t.centroids[i].mean-value < value-t.centroids[i-1].mean {
+		return i, true
+	}
+	return i - 1, true
+}
+
+// cumulativeQuantile returns the quantile of the centroid's own cumulative
+// weight, i.e. the weight of every centroid before it plus half its own.
+func (t *TDigest) cumulativeQuantile(idx int) float64 {
+	cumulative := t.centroids[idx].weight / 2
+	for i := 0; i < idx; i++ {
+		cumulative += t.centroids[i].weight
+	}
+	if t.totalWeight == 0 {
+		return 0
+	}
+	return cumulative / t.totalWeight
+}
+
+// sizeBound is the maximum weight a centroid at quantile q may hold before
+// it must split into a new centroid. It scales with totalWeight so the
+// centroid count stays bounded (~delta) as more samples are added, rather
+// than growing with the sample count.
+func (t *TDigest) sizeBound(q float64) float64 {
+	return 4 * t.totalWeight * q * (1 - q) / t.delta
+}
+
+// insert adds a brand new centroid, keeping centroids sorted by mean.
+func (t *TDigest) insert(c centroid) {
+	i := sort.Search(len(t.centroids), func(i int) bool { return t.centroids[i].mean >= c.mean })
+	t.centroids = append(t.centroids, centroid{})
+	copy(t.centroids[i+1:], t.centroids[i:])
+	t.centroids[i] = c
+}
+
+// maybeCompress re-merges all centroids, in random order, once their count
+// grows large enough to start hurting Quantile's accuracy and Add's cost.
+func (t *TDigest) maybeCompress() {
+	if t.compressing || len(t.centroids) <= int(10*t.delta) {
+		return
+	}
+
+	old := t.centroids
+	rand.Shuffle(len(old), func(i, j int) { old[i], old[j] = old[j], old[i] })
+
+	t.centroids = nil
+	t.totalWeight = 0
+	t.compressing = true
+	for _, c := range old {
+		t.Add(c.mean, c.weight)
+	}
+	t.compressing = false
+}
@@ -0,0 +1,82 @@
+package tdigest
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+// TestQuantileAccuracy checks that the digest's reported 10/20/.../99
+// percentiles (plus an arbitrary user quantile) are close to the true
+// values for a known distribution.
+func TestQuantileAccuracy(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	values := make([]float64, 100000)
+	for i := range values {
+		values[i] = rng.Float64() * 1000
+	}
+
+	td := New(DefaultCompression)
+	for _, v := range values {
+		td.Add(v, 1)
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	quantiles := []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 0.95, 0.98, 0.99, 0.6789}
+	for _, q := range quantiles {
+		want := sorted[int(q*float64(len(sorted)-1))]
+		got := td.Quantile(q)
+		if diff := got - want; diff < -20 || diff > 20 {
+			t.Errorf("Quantile(%v) = %v, want close to %v (diff %v)", q, got, want, diff)
+		}
+	}
+}
+
+// TestAddThroughput guards against the centroid-count-grows-with-sample-count
+// regression: sizeBound must scale with totalWeight, or Add degrades to
+// O(n) once the digest passes its recompress threshold, making large runs
+// never finish.
+func TestAddThroughput(t *testing.T) {
+	const n = 200000
+	td := New(DefaultCompression)
+	rng := rand.New(rand.NewSource(2))
+
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		td.Add(rng.Float64()*1000, 1)
+	}
+	elapsed := time.Since(start)
+
+	if elapsed > 2*time.Second {
+		t.Fatalf("Add-ing %d samples took %v, want well under 2s (sizeBound may not be scaling with totalWeight)", n, elapsed)
+	}
+}
+
+// TestConcentratedValuesCompress guards against the duplicate-value
+// regression: a stream dominated by one repeated value (e.g. a mostly-empty
+// queueLen histogram) must still compress down to roughly delta centroids
+// instead of growing a new centroid per Add because ties always land in and
+// get matched against the same low-weight slot.
+func TestConcentratedValuesCompress(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	td := New(DefaultCompression)
+
+	const n = 3000
+	for i := 0; i < n; i++ {
+		if rng.Float64() < 0.98 {
+			td.Add(0, 1)
+		} else {
+			td.Add(rng.Float64()*1000, 1)
+		}
+	}
+
+	if got := len(td.centroids); got > int(10*td.delta) {
+		t.Fatalf("len(centroids) = %d after %d samples, want well under %d (duplicate values aren't merging)", got, n, int(10*td.delta))
+	}
+	if got := td.Quantile(0.5); got != 0 {
+		t.Errorf("Quantile(0.5) = %v, want 0 (median of a 98%%-zero stream)", got)
+	}
+}